@@ -0,0 +1,160 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/HotelsDotCom/go-docker-client/dockererr"
+	"github.com/HotelsDotCom/go-logger"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/builder/dockerignore"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"golang.org/x/net/context"
+)
+
+// BuildRequest builds an image from a Dockerfile and then runs it, as if
+// RunWithOptions(RunOptions) had been called with Image set to the result.
+// Exactly one of ContextDir or ContextReader must be set.
+type BuildRequest struct {
+	RunOptions
+
+	// ContextDir is a directory to tar up as the build context, honouring
+	// a .dockerignore file in its root if present.
+	ContextDir string
+
+	// ContextReader is an already-built build context tar stream. Use this
+	// instead of ContextDir when the caller already has one (e.g. from a
+	// remote source).
+	ContextReader io.Reader
+
+	// Dockerfile is the Dockerfile's path within the build context.
+	// Defaults to "Dockerfile".
+	Dockerfile string
+
+	// BuildArgs are passed through to `ARG` instructions in the Dockerfile.
+	BuildArgs map[string]*string
+
+	// Target is the build stage to stop at, for multi-stage Dockerfiles.
+	// Empty means build the last stage.
+	Target string
+
+	// Tag is the image tag to assign the built image. Defaults to
+	// "go-docker-client/build:<session id>".
+	Tag string
+}
+
+func (d *docker) BuildAndRun(ctx context.Context, req BuildRequest) (Container, error) {
+	tag := req.Tag
+	if tag == "" {
+		tag = fmt.Sprintf("go-docker-client/build:%s", d.sessionID)
+	}
+
+	if err := d.build(ctx, req, tag); err != nil {
+		return nil, err
+	}
+
+	opts := req.RunOptions
+	opts.Image = tag
+	return d.RunWithOptions(opts)
+}
+
+func (d *docker) build(ctx context.Context, req BuildRequest, tag string) error {
+	buildContext, err := req.buildContext()
+	if err != nil {
+		return err
+	}
+	if closer, ok := buildContext.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	dockerfile := req.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+
+	resp, err := d.cli.ImageBuild(ctx, buildContext, types.ImageBuildOptions{
+		Dockerfile: dockerfile,
+		BuildArgs:  req.BuildArgs,
+		Target:     req.Target,
+		Labels:     d.mergeSessionLabels(req.Labels),
+		Tags:       []string{tag},
+	})
+	if err != nil {
+		return dockererr.Wrap(err)
+	}
+	defer resp.Body.Close()
+
+	return streamBuildOutput(resp.Body)
+}
+
+// buildContext resolves the request's build context to a tar stream,
+// honouring a .dockerignore file when building from ContextDir.
+func (req BuildRequest) buildContext() (io.Reader, error) {
+	if req.ContextReader != nil {
+		return req.ContextReader, nil
+	}
+	if req.ContextDir == "" {
+		return nil, errors.New("docker: BuildRequest needs a ContextDir or ContextReader")
+	}
+
+	excludes, err := readDockerignore(req.ContextDir)
+	if err != nil {
+		return nil, err
+	}
+	return archive.TarWithOptions(req.ContextDir, &archive.TarOptions{ExcludePatterns: excludes})
+}
+
+func readDockerignore(contextDir string) ([]string, error) {
+	data, err := ioutil.ReadFile(contextDir + "/.dockerignore")
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dockerignore.ReadAll(bytes.NewReader(data))
+}
+
+// streamBuildOutput logs each line of the daemon's build output, returning
+// the build's own error if it reported one.
+func streamBuildOutput(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if msg.Stream != "" {
+			logger.Infof(strings.TrimSuffix(msg.Stream, "\n"))
+		}
+	}
+}
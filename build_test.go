@@ -0,0 +1,89 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestBuildAndRunShouldBuildThenRunTheResultingImage(t *testing.T) {
+	var buildOptions types.ImageBuildOptions
+	imageBuilder := func(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error) {
+		buildOptions = options
+		body, _ := ioutil.ReadAll(buildContext)
+		require.Equal(t, "a fake tar stream", string(body))
+		return types.ImageBuildResponse{Body: ioutil.NopCloser(strings.NewReader(
+			"{\"stream\":\"Step 1/1 : FROM scratch\\n\"}\n",
+		))}, nil
+	}
+
+	var createdImage string
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+		createdImage = config.Image
+		return container.ContainerCreateCreatedBody{ID: "aContainerId"}, nil
+	}
+
+	mdc := &mockDockerClient{imageBuilder: imageBuilder, containerCreate: containerCreate}
+	d := &docker{cli: mdc, ctx: context.Background()}
+
+	c, err := d.BuildAndRun(context.Background(), BuildRequest{
+		RunOptions:    RunOptions{Name: "name"},
+		ContextReader: strings.NewReader("a fake tar stream"),
+		Tag:           "my-service:test",
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, []string{"my-service:test"}, buildOptions.Tags)
+	assert.Equal(t, "my-service:test", createdImage)
+}
+
+func TestBuildAndRunShouldReturnErrorWhenTheBuildFails(t *testing.T) {
+	imageBuilder := func(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error) {
+		return types.ImageBuildResponse{Body: ioutil.NopCloser(strings.NewReader(
+			"{\"errorDetail\":{\"message\":\"no such file: Dockerfile\"},\"error\":\"no such file: Dockerfile\"}\n",
+		))}, nil
+	}
+
+	mdc := &mockDockerClient{imageBuilder: imageBuilder}
+	d := &docker{cli: mdc, ctx: context.Background()}
+
+	_, err := d.BuildAndRun(context.Background(), BuildRequest{
+		ContextReader: strings.NewReader("a fake tar stream"),
+	})
+
+	assert.EqualError(t, err, "no such file: Dockerfile")
+}
+
+func TestBuildAndRunShouldReturnErrorWhenNeitherContextDirNorReaderIsSet(t *testing.T) {
+	d := &docker{cli: &mockDockerClient{}, ctx: context.Background()}
+
+	_, err := d.BuildAndRun(context.Background(), BuildRequest{})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"fmt"
+
+	"github.com/HotelsDotCom/go-docker-client/dockererr"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"golang.org/x/net/context"
+)
+
+// Container is a running container started by Docker.Run.
+type Container interface {
+	// StopAndRemove stops the container and removes it. Its anonymous
+	// volumes are not removed.
+	StopAndRemove() error
+
+	// GetIP returns the container's IP address on the default bridge
+	// network, or an empty string if it has none.
+	GetIP() (string, error)
+
+	// MappedPort returns the host port containerPort (e.g. "27017/tcp")
+	// was actually published on. Use this, not GetIP, to connect from the
+	// test process - container IPs aren't reachable on Docker for
+	// Mac/Windows.
+	MappedPort(ctx context.Context, containerPort nat.Port) (nat.Port, error)
+
+	// Endpoint returns a "<scheme>://host:mappedPort" URI for containerPort,
+	// e.g. Endpoint(ctx, "27017/tcp", "mongodb").
+	Endpoint(ctx context.Context, containerPort nat.Port, scheme string) (string, error)
+
+	// ConnectTo attaches the container to net, reachable by other
+	// containers on it under aliases (if any), in addition to the
+	// networks it was started with.
+	ConnectTo(ctx context.Context, net Network, aliases ...string) error
+
+	// DisconnectFrom detaches the container from net.
+	DisconnectFrom(ctx context.Context, net Network) error
+}
+
+type dockerContainer struct {
+	id  string
+	cli dockerClient
+	ctx context.Context
+}
+
+func (c *dockerContainer) StopAndRemove() error {
+	if err := c.cli.ContainerStop(c.ctx, c.id, nil); err != nil {
+		return dockererr.Wrap(err)
+	}
+	return dockererr.Wrap(c.cli.ContainerRemove(c.ctx, c.id, types.ContainerRemoveOptions{}))
+}
+
+func (c *dockerContainer) GetIP() (string, error) {
+	json, err := c.cli.ContainerInspect(c.ctx, c.id)
+	if err != nil {
+		return "", dockererr.Wrap(err)
+	}
+	if json.NetworkSettings == nil {
+		return "", nil
+	}
+	return json.NetworkSettings.IPAddress, nil
+}
+
+// dockerHost is the address test code should dial to reach a published
+// container port. Docker Desktop (Mac/Windows) only exposes published ports
+// on the loopback interface of the host running the CLI, not the container's
+// own IP, so this - not GetIP - is what MappedPort/Endpoint are paired with.
+const dockerHost = "localhost"
+
+func (c *dockerContainer) MappedPort(ctx context.Context, containerPort nat.Port) (nat.Port, error) {
+	return mappedPort(ctx, c.cli, c.id, containerPort)
+}
+
+// mappedPort looks up the host port containerPort was published on for
+// containerID. It's shared by Container.MappedPort and the wait
+// strategies that need to dial a container through its published ports
+// rather than its internal bridge IP, which isn't reachable from the test
+// process on Docker for Mac/Windows.
+func mappedPort(ctx context.Context, cli dockerClient, containerID string, containerPort nat.Port) (nat.Port, error) {
+	json, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return "", dockererr.Wrap(err)
+	}
+	if json.NetworkSettings == nil {
+		return "", fmt.Errorf("container %s has no network settings", containerID)
+	}
+
+	bindings, ok := json.NetworkSettings.Ports[containerPort]
+	if !ok || len(bindings) == 0 {
+		return "", fmt.Errorf("port %s is not published on container %s", containerPort, containerID)
+	}
+	return nat.Port(bindings[0].HostPort), nil
+}
+
+func (c *dockerContainer) Endpoint(ctx context.Context, containerPort nat.Port, scheme string) (string, error) {
+	mapped, err := c.MappedPort(ctx, containerPort)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s://%s:%s", scheme, dockerHost, mapped.Port()), nil
+}
+
+func (c *dockerContainer) ConnectTo(ctx context.Context, net Network, aliases ...string) error {
+	return dockererr.Wrap(c.cli.NetworkConnect(ctx, net.ID(), c.id, &network.EndpointSettings{Aliases: aliases}))
+}
+
+func (c *dockerContainer) DisconnectFrom(ctx context.Context, net Network) error {
+	return dockererr.Wrap(c.cli.NetworkDisconnect(ctx, net.ID(), c.id, false))
+}
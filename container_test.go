@@ -18,9 +18,13 @@ package docker
 
 import (
 	"errors"
+	"github.com/HotelsDotCom/go-docker-client/dockererr"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/go-connections/nat"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
@@ -46,8 +50,24 @@ func TestStopAndRemoveShouldBeCalledAndReturnAnError(t *testing.T) {
 	assert.EqualError(t, err, "the error")
 }
 
+func TestStopAndRemoveShouldReturnAnUnavailableErrorWhenTheDaemonTagsStopAsUnavailable(t *testing.T) {
+	containerStopper := func(ctx context.Context, containerID string, timeout *time.Duration) error {
+		return errdefs.Unavailable(errors.New("daemon is shutting down"))
+	}
+
+	mdc := &mockDockerClient{containerStop: containerStopper}
+
+	c := &docker{cli: mdc}
+	dockerContainer, _ := c.Run("name", "path", nil, nil)
+
+	err := dockerContainer.StopAndRemove()
+
+	assert.EqualError(t, err, "daemon is shutting down")
+	assert.True(t, dockererr.IsUnavailable(err), "should be a dockererr.Unavailable")
+}
+
 func TestStopAndRemoveShouldBeCalledWithASpecificContainerId(t *testing.T) {
-	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
 		return container.ContainerCreateCreatedBody{ID: "aContainerId"}, nil
 	}
 
@@ -89,7 +109,7 @@ func TestGetIPShouldBeCalledAndPassAnErrorOnFailure(t *testing.T) {
 }
 
 func TestGetIPShouldBeCalledWithASpecificContainerId(t *testing.T) {
-	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
 		return container.ContainerCreateCreatedBody{ID: "aContainerId"}, nil
 	}
 
@@ -113,7 +133,7 @@ func TestGetIPShouldBeCalledWithASpecificContainerId(t *testing.T) {
 
 func TestGetIPShouldReturnAnIPAddress(t *testing.T) {
 
-	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
 		return container.ContainerCreateCreatedBody{ID: "aContainerId"}, nil
 	}
 
@@ -140,7 +160,7 @@ func TestGetIPShouldReturnAnIPAddress(t *testing.T) {
 }
 
 func TestGetIPShouldBeEmptyIfNetworkSettingsIsNil(t *testing.T) {
-	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
 		return container.ContainerCreateCreatedBody{ID: "aContainerId"}, nil
 	}
 
@@ -161,3 +181,62 @@ func TestGetIPShouldBeEmptyIfNetworkSettingsIsNil(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Empty(t, ip)
 }
+
+func TestMappedPortShouldReturnTheHostPortTheContainerPortWasPublishedOn(t *testing.T) {
+	containerInspect := func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+		return types.ContainerJSON{
+			NetworkSettings: &types.NetworkSettings{
+				NetworkSettingsBase: types.NetworkSettingsBase{
+					Ports: nat.PortMap{
+						nat.Port("27017/tcp"): []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "49153"}},
+					},
+				},
+			},
+		}, nil
+	}
+
+	mdc := &mockDockerClient{containerInspect: containerInspect}
+	c := &dockerContainer{id: "aContainerId", cli: mdc, ctx: context.Background()}
+
+	mapped, err := c.MappedPort(context.Background(), "27017/tcp")
+
+	require.NoError(t, err)
+	assert.Equal(t, nat.Port("49153"), mapped)
+}
+
+func TestMappedPortShouldReturnErrorWhenThePortWasNotPublished(t *testing.T) {
+	containerInspect := func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+		return types.ContainerJSON{
+			NetworkSettings: &types.NetworkSettings{},
+		}, nil
+	}
+
+	mdc := &mockDockerClient{containerInspect: containerInspect}
+	c := &dockerContainer{id: "aContainerId", cli: mdc, ctx: context.Background()}
+
+	_, err := c.MappedPort(context.Background(), "27017/tcp")
+
+	assert.Error(t, err)
+}
+
+func TestEndpointShouldReturnAUriBuiltFromTheMappedPort(t *testing.T) {
+	containerInspect := func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+		return types.ContainerJSON{
+			NetworkSettings: &types.NetworkSettings{
+				NetworkSettingsBase: types.NetworkSettingsBase{
+					Ports: nat.PortMap{
+						nat.Port("27017/tcp"): []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "49153"}},
+					},
+				},
+			},
+		}, nil
+	}
+
+	mdc := &mockDockerClient{containerInspect: containerInspect}
+	c := &dockerContainer{id: "aContainerId", cli: mdc, ctx: context.Background()}
+
+	endpoint, err := c.Endpoint(context.Background(), "27017/tcp", "mongodb")
+
+	require.NoError(t, err)
+	assert.Equal(t, "mongodb://localhost:49153", endpoint)
+}
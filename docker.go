@@ -0,0 +1,264 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package docker provides a small, test-friendly wrapper around the Docker
+// Engine API for starting and stopping containers from integration tests.
+package docker
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/HotelsDotCom/go-docker-client/dockererr"
+	"github.com/HotelsDotCom/go-logger"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/net/context"
+)
+
+// Docker runs and manages containers for the lifetime of a test process.
+type Docker interface {
+	// Run pulls imagePath if it is not already present locally, creates and
+	// starts a container called name from it, and returns a handle to it.
+	Run(name, imagePath string, env []string, ports []string) (Container, error)
+
+	// RunWithOptions is the full-featured form of Run: it additionally
+	// supports pinning an OCI platform, labels, networks and mounts. A
+	// locally cached image that doesn't match opts.Platform is re-pulled.
+	RunWithOptions(opts RunOptions) (Container, error)
+
+	// RunAndWait runs opts like RunWithOptions, then blocks until strategy
+	// reports the container ready or ctx is done. If the strategy never
+	// becomes ready, the container is stopped and removed before the error
+	// is returned, so callers don't leak a half-started container.
+	RunAndWait(ctx context.Context, opts RunOptions, strategy WaitStrategy) (Container, error)
+
+	// Prune removes every reaper-labelled container and network whose
+	// session label is not this session's. It never removes containers
+	// still owned by this session; it does not check which test binary
+	// created the others or whether their session is still running.
+	Prune() error
+
+	// BuildAndRun builds req's Dockerfile, then runs the resulting image as
+	// if RunWithOptions(req.RunOptions) had been called with Image set to
+	// the built image.
+	BuildAndRun(ctx context.Context, req BuildRequest) (Container, error)
+
+	// CreateNetwork creates a user-defined network that containers can
+	// communicate over by name, either via RunOptions.Networks or
+	// Container.ConnectTo.
+	CreateNetwork(name string, opts NetworkOptions) (Network, error)
+}
+
+// dockerClient is the subset of the Docker Engine API client that this
+// package depends on. It exists so tests can supply a mock implementation.
+type dockerClient interface {
+	ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
+	ImagePull(ctx context.Context, refStr string, options types.ImagePullOptions) (io.ReadCloser, error)
+	ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
+	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error)
+	ContainerStart(ctx context.Context, containerID string, options types.ContainerStartOptions) error
+	ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error
+	ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
+	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+	ContainerLogs(ctx context.Context, containerID string, options types.ContainerLogsOptions) (io.ReadCloser, error)
+	ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error)
+	ImageBuild(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error)
+	NetworkCreate(ctx context.Context, name string, options types.NetworkCreate) (types.NetworkCreateResponse, error)
+	NetworkRemove(ctx context.Context, networkID string) error
+	NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error)
+	NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error
+	NetworkDisconnect(ctx context.Context, networkID, containerID string, force bool) error
+}
+
+// newDockerClient is a seam for tests; it normally builds a real Docker
+// Engine API client from the environment (DOCKER_HOST, DOCKER_CERT_PATH, etc).
+var newDockerClient = func() (dockerClient, error) {
+	return client.NewEnvClient()
+}
+
+type docker struct {
+	cli dockerClient
+	ctx context.Context
+
+	sessionID     string
+	sessionLabels map[string]string
+	reaperEnabled bool
+}
+
+// NewDocker creates a Docker client from the environment.
+func NewDocker() (Docker, error) {
+	return NewDockerWithOptions()
+}
+
+func (d *docker) Run(name, imagePath string, env []string, ports []string) (Container, error) {
+	return d.RunWithOptions(RunOptions{Name: name, Image: imagePath, Env: env, Ports: ports})
+}
+
+func (d *docker) RunWithOptions(opts RunOptions) (Container, error) {
+	if err := d.ensureImage(opts.Image, opts.Platform); err != nil {
+		return nil, err
+	}
+
+	exposedPorts, bindings, err := nat.ParsePortSpecs(opts.Ports)
+	if err != nil {
+		return nil, dockererr.Wrap(err)
+	}
+
+	specExposedPorts, specBindings := natPortBindings(opts.PortSpecs)
+	for port := range specExposedPorts {
+		exposedPorts[port] = struct{}{}
+	}
+	for port, b := range specBindings {
+		bindings[port] = append(bindings[port], b...)
+	}
+
+	config := &container.Config{
+		Image:        opts.Image,
+		Env:          opts.Env,
+		ExposedPorts: exposedPorts,
+		Labels:       d.mergeSessionLabels(opts.Labels),
+	}
+	hostConfig := &container.HostConfig{
+		PortBindings: bindings,
+		Mounts:       opts.Mounts,
+	}
+
+	created, err := d.cli.ContainerCreate(d.ctx, config, hostConfig, networkingConfig(opts), opts.Platform, opts.Name)
+	if err != nil {
+		return nil, dockererr.Wrap(err)
+	}
+
+	if err := d.cli.ContainerStart(d.ctx, created.ID, types.ContainerStartOptions{}); err != nil {
+		return nil, dockererr.Wrap(err)
+	}
+
+	return &dockerContainer{id: created.ID, cli: d.cli, ctx: d.ctx}, nil
+}
+
+func (d *docker) RunAndWait(ctx context.Context, opts RunOptions, strategy WaitStrategy) (Container, error) {
+	c, err := d.RunWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	dc := c.(*dockerContainer)
+	if err := strategy.Wait(ctx, dc.cli, dc.id); err != nil {
+		c.StopAndRemove()
+		return nil, err
+	}
+	return c, nil
+}
+
+// networkingConfig builds the per-network endpoint settings ContainerCreate
+// needs to attach a container to opts.Networks at creation time, applying
+// any aliases from opts.NetworkAliases. It returns nil when opts.Networks
+// is empty, leaving the container on the default bridge network.
+func networkingConfig(opts RunOptions) *network.NetworkingConfig {
+	if len(opts.Networks) == 0 {
+		return nil
+	}
+
+	endpoints := make(map[string]*network.EndpointSettings, len(opts.Networks))
+	for _, name := range opts.Networks {
+		endpoints[name] = &network.EndpointSettings{Aliases: opts.NetworkAliases[name]}
+	}
+	return &network.NetworkingConfig{EndpointsConfig: endpoints}
+}
+
+// ensureImage makes sure imagePath is present locally and, when platform is
+// given, that the cached image actually matches it - mirroring the daemon's
+// own platform-aware pull behaviour (API 1.41+). A wrong-platform image is
+// re-pulled rather than silently run under emulation.
+func (d *docker) ensureImage(imagePath string, platform *specs.Platform) error {
+	hasImage, err := d.hasImage(imagePath)
+	if err != nil {
+		return err
+	}
+
+	if hasImage && platform != nil {
+		matches, err := d.imageMatchesPlatform(imagePath, platform)
+		if err != nil {
+			return err
+		}
+		hasImage = matches
+	}
+
+	if hasImage {
+		return nil
+	}
+	return d.pullImage(imagePath, platform)
+}
+
+func (d *docker) hasImage(imagePath string) (bool, error) {
+	filterArgs := filters.NewArgs()
+	filterArgs.Add("reference", imagePath)
+
+	images, err := d.cli.ImageList(d.ctx, types.ImageListOptions{Filters: filterArgs})
+	if err != nil {
+		return false, dockererr.Wrap(err)
+	}
+	return len(images) > 0, nil
+}
+
+func (d *docker) imageMatchesPlatform(imagePath string, platform *specs.Platform) (bool, error) {
+	inspect, _, err := d.cli.ImageInspectWithRaw(d.ctx, imagePath)
+	if err != nil {
+		return false, dockererr.Wrap(err)
+	}
+	if inspect.Os != platform.OS || inspect.Architecture != platform.Architecture {
+		return false, nil
+	}
+	if platform.Variant != "" && inspect.Variant != platform.Variant {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (d *docker) pullImage(imagePath string, platform *specs.Platform) error {
+	options := types.ImagePullOptions{}
+	if platform != nil {
+		options.Platform = platformString(platform)
+	}
+
+	reader, err := d.cli.ImagePull(d.ctx, imagePath, options)
+	if err != nil {
+		err = dockererr.Wrap(err)
+		logger.Errorf("unable to pull image: %s", err)
+		return err
+	}
+	defer reader.Close()
+
+	_, err = io.Copy(ioutil.Discard, reader)
+	return err
+}
+
+// platformString renders an OCI platform the way the Docker API expects it
+// on the wire, e.g. "linux/arm64/v8".
+func platformString(platform *specs.Platform) string {
+	s := fmt.Sprintf("%s/%s", platform.OS, platform.Architecture)
+	if platform.Variant != "" {
+		s = fmt.Sprintf("%s/%s", s, platform.Variant)
+	}
+	return s
+}
@@ -17,10 +17,13 @@ package docker
 
 import (
 	"errors"
+	"github.com/HotelsDotCom/go-docker-client/dockererr"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/go-connections/nat"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
@@ -99,6 +102,34 @@ func TestRunShouldReturnErrorWhenHasImageFails(t *testing.T) {
 	assert.EqualError(t, err, "the error")
 }
 
+func TestRunShouldReturnANotFoundErrorWhenTheDaemonTagsImageListAsNotFound(t *testing.T) {
+
+	mdc := &mockDockerClient{imageLister: func(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+		return []types.ImageSummary{}, errdefs.NotFound(errors.New("no such image"))
+	}}
+	c := &docker{cli: mdc}
+
+	_, err := c.Run("name", "path", nil, nil)
+
+	assert.EqualError(t, err, "no such image")
+	assert.True(t, dockererr.IsNotFound(err), "should be a dockererr.NotFound")
+}
+
+func TestRunShouldReturnAConflictErrorWhenTheDaemonTagsContainerCreateAsConflict(t *testing.T) {
+
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+		return container.ContainerCreateCreatedBody{}, errdefs.Conflict(errors.New("name is already in use"))
+	}
+
+	mdc := &mockDockerClient{containerCreate: containerCreate}
+	c := &docker{cli: mdc}
+
+	_, err := c.Run("name", "path", nil, nil)
+
+	assert.EqualError(t, err, "name is already in use")
+	assert.True(t, dockererr.IsConflict(err), "should be a dockererr.Conflict")
+}
+
 func TestShouldFilterImageListToRequiredImageName(t *testing.T) {
 	calledImageList := false
 	imageLister := func(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
@@ -177,7 +208,7 @@ func TestContainerCreateShouldCreateContainerWhenCalled(t *testing.T) {
 
 	calledContainerCreate := false
 
-	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
 		calledContainerCreate = true
 		require.True(t, config.Image == "imagePath", "should be imagePath")
 		require.Equal(t, config.Env, []string{"BANANA=YELLOW"})
@@ -197,7 +228,7 @@ func TestContainerCreateShouldCreateContainerWhenCalled(t *testing.T) {
 
 func TestCreateContainerShouldReturnErrorWhenContainerCreateFails(t *testing.T) {
 
-	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
 		return container.ContainerCreateCreatedBody{}, errors.New("containerCreate Failed")
 	}
 
@@ -213,7 +244,7 @@ func TestCreateContainerShouldReturnErrorWhenContainerCreateFails(t *testing.T)
 
 func TestCreateContainerShouldHaveEnvironmentVariablesSet(t *testing.T) {
 
-	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
 		require.Equal(t, config.Env, []string{"BANANA=YELLOW"})
 		return container.ContainerCreateCreatedBody{}, nil
 	}
@@ -247,7 +278,7 @@ func TestCreateContainerShouldHaveEnvironmentVariablesSet(t *testing.T) {
 
 func TestRunShouldStartAndReturnContainerWithCorrectContainerID(t *testing.T) {
 
-	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
 		return container.ContainerCreateCreatedBody{ID: "aContainerId"}, nil
 	}
 
@@ -291,6 +322,145 @@ func TestStartContainerShouldReturnErrorWhenFails(t *testing.T) {
 	assert.EqualError(t, err, "the error")
 }
 
+func TestRunAndWaitShouldReturnTheContainerOnceTheStrategySucceeds(t *testing.T) {
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+		return container.ContainerCreateCreatedBody{ID: "aContainerId"}, nil
+	}
+
+	mdc := &mockDockerClient{containerCreate: containerCreate}
+	c := &docker{cli: mdc}
+
+	readyStrategy := waitStrategyFunc(func(ctx context.Context, cli dockerClient, containerID string) error {
+		assert.Equal(t, "aContainerId", containerID)
+		return nil
+	})
+
+	container, err := c.RunAndWait(context.Background(), RunOptions{Name: "name", Image: "imagePath"}, readyStrategy)
+
+	require.NoError(t, err)
+	require.NotNil(t, container)
+}
+
+func TestRunAndWaitShouldStopAndRemoveTheContainerWhenTheStrategyFails(t *testing.T) {
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+		return container.ContainerCreateCreatedBody{ID: "aContainerId"}, nil
+	}
+
+	var stoppedContainerId string
+	containerStop := func(ctx context.Context, containerID string, timeout *time.Duration) error {
+		stoppedContainerId = containerID
+		return nil
+	}
+
+	mdc := &mockDockerClient{containerCreate: containerCreate, containerStop: containerStop}
+	c := &docker{cli: mdc}
+
+	neverReadyStrategy := waitStrategyFunc(func(ctx context.Context, cli dockerClient, containerID string) error {
+		return errors.New("never ready")
+	})
+
+	_, err := c.RunAndWait(context.Background(), RunOptions{Name: "name", Image: "imagePath"}, neverReadyStrategy)
+
+	assert.EqualError(t, err, "never ready")
+	assert.Equal(t, "aContainerId", stoppedContainerId)
+}
+
+func TestRunWithOptionsShouldNotPullWhenTheCachedImageMatchesThePlatform(t *testing.T) {
+	calledImagePull := false
+	imagePuller := func(ctx context.Context, refStr string, options types.ImagePullOptions) (io.ReadCloser, error) {
+		calledImagePull = true
+		return &mockReadCloser{}, nil
+	}
+	imageLister := func(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+		return []types.ImageSummary{{ID: "anImageId"}}, nil
+	}
+	imageInspector := func(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+		return types.ImageInspect{Os: "linux", Architecture: "arm64", Variant: "v8"}, nil, nil
+	}
+
+	mdc := &mockDockerClient{imagePuller: imagePuller, imageLister: imageLister, imageInspector: imageInspector}
+	c := &docker{cli: mdc}
+
+	_, err := c.RunWithOptions(RunOptions{Name: "name", Image: "path", Platform: &specs.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}})
+
+	require.NoError(t, err)
+	assert.False(t, calledImagePull, "should not re-pull an image that already matches the requested platform")
+}
+
+func TestRunWithOptionsShouldRePullWhenTheCachedImageDoesNotMatchThePlatform(t *testing.T) {
+	var pulledPlatform string
+	imagePuller := func(ctx context.Context, refStr string, options types.ImagePullOptions) (io.ReadCloser, error) {
+		pulledPlatform = options.Platform
+		return &mockReadCloser{}, nil
+	}
+	imageLister := func(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+		return []types.ImageSummary{{ID: "anImageId"}}, nil
+	}
+	imageInspector := func(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+		return types.ImageInspect{Os: "linux", Architecture: "amd64"}, nil, nil
+	}
+
+	mdc := &mockDockerClient{imagePuller: imagePuller, imageLister: imageLister, imageInspector: imageInspector}
+	c := &docker{cli: mdc}
+
+	_, err := c.RunWithOptions(RunOptions{Name: "name", Image: "path", Platform: &specs.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "linux/arm64/v8", pulledPlatform)
+}
+
+func TestRunWithOptionsShouldPullWhenTheImageIsNotCachedAtAll(t *testing.T) {
+	calledImagePull := false
+	imagePuller := func(ctx context.Context, refStr string, options types.ImagePullOptions) (io.ReadCloser, error) {
+		calledImagePull = true
+		return &mockReadCloser{}, nil
+	}
+	imageLister := func(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+		return []types.ImageSummary{}, nil
+	}
+
+	mdc := &mockDockerClient{imagePuller: imagePuller, imageLister: imageLister}
+	c := &docker{cli: mdc}
+
+	_, err := c.RunWithOptions(RunOptions{Name: "name", Image: "path", Platform: &specs.Platform{OS: "linux", Architecture: "amd64"}})
+
+	require.NoError(t, err)
+	assert.True(t, calledImagePull)
+}
+
+func TestRunWithOptionsShouldPassThePlatformToContainerCreate(t *testing.T) {
+	var createdPlatform *specs.Platform
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+		createdPlatform = platform
+		return container.ContainerCreateCreatedBody{ID: "aContainerId"}, nil
+	}
+
+	mdc := &mockDockerClient{containerCreate: containerCreate}
+	c := &docker{cli: mdc}
+
+	_, err := c.RunWithOptions(RunOptions{Name: "name", Image: "path", Platform: &specs.Platform{OS: "linux", Architecture: "arm64"}})
+
+	require.NoError(t, err)
+	require.NotNil(t, createdPlatform)
+	assert.Equal(t, "linux", createdPlatform.OS)
+	assert.Equal(t, "arm64", createdPlatform.Architecture)
+}
+
+func TestPlatformStringShouldRenderOsAndArchitecture(t *testing.T) {
+	assert.Equal(t, "linux/amd64", platformString(&specs.Platform{OS: "linux", Architecture: "amd64"}))
+}
+
+func TestPlatformStringShouldIncludeVariantWhenSet(t *testing.T) {
+	assert.Equal(t, "linux/arm64/v8", platformString(&specs.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}))
+}
+
+// waitStrategyFunc adapts a plain function to WaitStrategy for tests.
+type waitStrategyFunc func(ctx context.Context, cli dockerClient, containerID string) error
+
+func (f waitStrategyFunc) Wait(ctx context.Context, cli dockerClient, containerID string) error {
+	return f(ctx, cli, containerID)
+}
+
 type mockReadCloser struct {
 	closer func() error
 }
@@ -309,11 +479,20 @@ func (m *mockReadCloser) Close() error {
 type mockDockerClient struct {
 	imageLister      func(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
 	imagePuller      func(ctx context.Context, refStr string, options types.ImagePullOptions) (io.ReadCloser, error)
-	containerCreate  func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error)
+	imageInspector   func(ctx context.Context, imageID string) (types.ImageInspect, []byte, error)
+	containerCreate  func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error)
 	containerStart   func(ctx context.Context, containerID string, options types.ContainerStartOptions) error
 	containerInspect func(ctx context.Context, containerID string) (types.ContainerJSON, error)
 	containerStop    func(ctx context.Context, containerID string, timeout *time.Duration) error
 	containerRemove  func(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+	containerLogs    func(ctx context.Context, containerID string, options types.ContainerLogsOptions) (io.ReadCloser, error)
+	containerList    func(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error)
+	imageBuilder     func(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error)
+	networkCreate    func(ctx context.Context, name string, options types.NetworkCreate) (types.NetworkCreateResponse, error)
+	networkRemove    func(ctx context.Context, networkID string) error
+	networkList      func(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error)
+	networkConnect   func(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error
+	networkDisconnect func(ctx context.Context, networkID, containerID string, force bool) error
 }
 
 func (m *mockDockerClient) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
@@ -330,9 +509,16 @@ func (m *mockDockerClient) ImagePull(ctx context.Context, refStr string, options
 	return &mockReadCloser{}, nil
 }
 
-func (m *mockDockerClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, containerName string) (container.ContainerCreateCreatedBody, error) {
+func (m *mockDockerClient) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	if m.imageInspector != nil {
+		return m.imageInspector(ctx, imageID)
+	}
+	return types.ImageInspect{}, nil, nil
+}
+
+func (m *mockDockerClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
 	if m.containerCreate != nil {
-		return m.containerCreate(ctx, config, hostConfig, networkingConfig, containerName)
+		return m.containerCreate(ctx, config, hostConfig, networkingConfig, platform, containerName)
 	}
 	return container.ContainerCreateCreatedBody{}, nil
 }
@@ -364,3 +550,59 @@ func (m *mockDockerClient) ContainerRemove(ctx context.Context, containerID stri
 	}
 	return nil
 }
+
+func (m *mockDockerClient) ContainerLogs(ctx context.Context, containerID string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	if m.containerLogs != nil {
+		return m.containerLogs(ctx, containerID, options)
+	}
+	return &mockReadCloser{}, nil
+}
+
+func (m *mockDockerClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	if m.containerList != nil {
+		return m.containerList(ctx, options)
+	}
+	return []types.Container{}, nil
+}
+
+func (m *mockDockerClient) ImageBuild(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error) {
+	if m.imageBuilder != nil {
+		return m.imageBuilder(ctx, buildContext, options)
+	}
+	return types.ImageBuildResponse{Body: &mockReadCloser{}}, nil
+}
+
+func (m *mockDockerClient) NetworkCreate(ctx context.Context, name string, options types.NetworkCreate) (types.NetworkCreateResponse, error) {
+	if m.networkCreate != nil {
+		return m.networkCreate(ctx, name, options)
+	}
+	return types.NetworkCreateResponse{}, nil
+}
+
+func (m *mockDockerClient) NetworkRemove(ctx context.Context, networkID string) error {
+	if m.networkRemove != nil {
+		return m.networkRemove(ctx, networkID)
+	}
+	return nil
+}
+
+func (m *mockDockerClient) NetworkList(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error) {
+	if m.networkList != nil {
+		return m.networkList(ctx, options)
+	}
+	return []types.NetworkResource{}, nil
+}
+
+func (m *mockDockerClient) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+	if m.networkConnect != nil {
+		return m.networkConnect(ctx, networkID, containerID, config)
+	}
+	return nil
+}
+
+func (m *mockDockerClient) NetworkDisconnect(ctx context.Context, networkID, containerID string, force bool) error {
+	if m.networkDisconnect != nil {
+		return m.networkDisconnect(ctx, networkID, containerID, force)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dockererr classifies errors coming back from the Docker daemon so
+// callers can branch on category (not found, conflict, ...) instead of
+// string-matching on Error(). It mirrors the classification the daemon
+// itself uses (see github.com/docker/docker/errdefs) so wrapping is
+// mechanical: Wrap inspects an error with the errdefs predicates and returns
+// one of the typed errors below, unchanged if none match.
+package dockererr
+
+import (
+	"errors"
+
+	"github.com/docker/docker/errdefs"
+)
+
+// NotFound is implemented by errors indicating a requested resource (image,
+// container, network...) does not exist.
+type NotFound interface {
+	error
+	NotFound()
+}
+
+// InvalidParameter is implemented by errors indicating a request was
+// malformed, e.g. an invalid platform or port spec.
+type InvalidParameter interface {
+	error
+	InvalidParameter()
+}
+
+// Conflict is implemented by errors indicating the request conflicts with
+// existing state, e.g. a container name already in use.
+type Conflict interface {
+	error
+	Conflict()
+}
+
+// Unauthorized is implemented by errors indicating the daemon rejected the
+// request for lack of (or invalid) credentials, e.g. a registry pull.
+type Unauthorized interface {
+	error
+	Unauthorized()
+}
+
+// Unavailable is implemented by errors indicating the daemon, or a
+// dependency of it, is temporarily unable to service the request.
+type Unavailable interface {
+	error
+	Unavailable()
+}
+
+// System is implemented by errors indicating an unexpected failure inside
+// the daemon itself.
+type System interface {
+	error
+	System()
+}
+
+// IsNotFound reports whether err is, or wraps, a NotFound error.
+func IsNotFound(err error) bool {
+	var e NotFound
+	return errors.As(err, &e)
+}
+
+// IsInvalidParameter reports whether err is, or wraps, an InvalidParameter error.
+func IsInvalidParameter(err error) bool {
+	var e InvalidParameter
+	return errors.As(err, &e)
+}
+
+// IsConflict reports whether err is, or wraps, a Conflict error.
+func IsConflict(err error) bool {
+	var e Conflict
+	return errors.As(err, &e)
+}
+
+// IsUnauthorized reports whether err is, or wraps, an Unauthorized error.
+func IsUnauthorized(err error) bool {
+	var e Unauthorized
+	return errors.As(err, &e)
+}
+
+// IsUnavailable reports whether err is, or wraps, an Unavailable error.
+func IsUnavailable(err error) bool {
+	var e Unavailable
+	return errors.As(err, &e)
+}
+
+// IsSystem reports whether err is, or wraps, a System error.
+func IsSystem(err error) bool {
+	var e System
+	return errors.As(err, &e)
+}
+
+// Wrap classifies err using the errdefs predicates the Docker daemon tags
+// its errors with, returning a typed error from this package. If err is nil,
+// or doesn't match any known category, it is returned unchanged.
+func Wrap(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errdefs.IsNotFound(err):
+		return &notFoundError{cause: err}
+	case errdefs.IsInvalidParameter(err):
+		return &invalidParameterError{cause: err}
+	case errdefs.IsConflict(err):
+		return &conflictError{cause: err}
+	case errdefs.IsUnauthorized(err):
+		return &unauthorizedError{cause: err}
+	case errdefs.IsUnavailable(err):
+		return &unavailableError{cause: err}
+	case errdefs.IsSystem(err):
+		return &systemError{cause: err}
+	default:
+		return err
+	}
+}
+
+type notFoundError struct{ cause error }
+
+func (e *notFoundError) Error() string { return e.cause.Error() }
+func (e *notFoundError) Unwrap() error { return e.cause }
+func (e *notFoundError) NotFound()     {}
+
+type invalidParameterError struct{ cause error }
+
+func (e *invalidParameterError) Error() string     { return e.cause.Error() }
+func (e *invalidParameterError) Unwrap() error     { return e.cause }
+func (e *invalidParameterError) InvalidParameter() {}
+
+type conflictError struct{ cause error }
+
+func (e *conflictError) Error() string { return e.cause.Error() }
+func (e *conflictError) Unwrap() error { return e.cause }
+func (e *conflictError) Conflict()     {}
+
+type unauthorizedError struct{ cause error }
+
+func (e *unauthorizedError) Error() string { return e.cause.Error() }
+func (e *unauthorizedError) Unwrap() error { return e.cause }
+func (e *unauthorizedError) Unauthorized() {}
+
+type unavailableError struct{ cause error }
+
+func (e *unavailableError) Error() string { return e.cause.Error() }
+func (e *unavailableError) Unwrap() error { return e.cause }
+func (e *unavailableError) Unavailable()  {}
+
+type systemError struct{ cause error }
+
+func (e *systemError) Error() string { return e.cause.Error() }
+func (e *systemError) Unwrap() error { return e.cause }
+func (e *systemError) System()       {}
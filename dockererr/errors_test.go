@@ -0,0 +1,81 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockererr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapShouldReturnNilWhenErrIsNil(t *testing.T) {
+	assert.Nil(t, Wrap(nil))
+}
+
+func TestWrapShouldReturnErrUnchangedWhenUncategorised(t *testing.T) {
+	err := errors.New("plain error")
+	assert.True(t, err == Wrap(err), "should return the very same error value")
+}
+
+func TestWrapShouldPreserveTheOriginalErrorMessage(t *testing.T) {
+	err := errdefs.NotFound(errors.New("no such image: mongo"))
+	require.EqualError(t, Wrap(err), "no such image: mongo")
+}
+
+func TestIsNotFoundShouldRecogniseAWrappedNotFoundError(t *testing.T) {
+	err := Wrap(errdefs.NotFound(errors.New("no such container")))
+	assert.True(t, IsNotFound(err))
+	assert.False(t, IsConflict(err))
+}
+
+func TestIsConflictShouldRecogniseAWrappedConflictError(t *testing.T) {
+	err := Wrap(errdefs.Conflict(errors.New("name already in use")))
+	assert.True(t, IsConflict(err))
+	assert.False(t, IsNotFound(err))
+}
+
+func TestIsUnauthorizedShouldRecogniseAWrappedUnauthorizedError(t *testing.T) {
+	err := Wrap(errdefs.Unauthorized(errors.New("authentication required")))
+	assert.True(t, IsUnauthorized(err))
+}
+
+func TestIsInvalidParameterShouldRecogniseAWrappedInvalidParameterError(t *testing.T) {
+	err := Wrap(errdefs.InvalidParameter(errors.New("invalid platform")))
+	assert.True(t, IsInvalidParameter(err))
+}
+
+func TestIsUnavailableShouldRecogniseAWrappedUnavailableError(t *testing.T) {
+	err := Wrap(errdefs.Unavailable(errors.New("daemon shutting down")))
+	assert.True(t, IsUnavailable(err))
+}
+
+func TestIsSystemShouldRecogniseAWrappedSystemError(t *testing.T) {
+	err := Wrap(errdefs.System(errors.New("internal error")))
+	assert.True(t, IsSystem(err))
+}
+
+func TestHelpersShouldReturnFalseForNilError(t *testing.T) {
+	assert.False(t, IsNotFound(nil))
+	assert.False(t, IsConflict(nil))
+	assert.False(t, IsUnauthorized(nil))
+	assert.False(t, IsInvalidParameter(nil))
+	assert.False(t, IsUnavailable(nil))
+	assert.False(t, IsSystem(nil))
+}
@@ -0,0 +1,116 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"github.com/HotelsDotCom/go-docker-client/dockererr"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"golang.org/x/net/context"
+)
+
+// Network is a user-defined network created by CreateNetwork. Containers
+// attached to the same network can reach each other by name or alias.
+type Network interface {
+	ID() string
+	Name() string
+
+	// Remove deletes the network. It fails while any container is still
+	// attached to it.
+	Remove() error
+}
+
+// NetworkOptions configures a network created by Docker.CreateNetwork.
+type NetworkOptions struct {
+	// Driver is the network driver, e.g. "bridge" or "overlay". Empty means
+	// the daemon's default, which is "bridge".
+	Driver string
+
+	// Labels are applied to the created network.
+	Labels map[string]string
+}
+
+type dockerNetwork struct {
+	id   string
+	name string
+	cli  dockerClient
+	ctx  context.Context
+}
+
+func (n *dockerNetwork) ID() string   { return n.id }
+func (n *dockerNetwork) Name() string { return n.name }
+
+func (n *dockerNetwork) Remove() error {
+	return dockererr.Wrap(n.cli.NetworkRemove(n.ctx, n.id))
+}
+
+// CreateNetwork creates a user-defined network that containers can be
+// attached to, either at creation time via RunOptions.Networks or
+// afterwards via Container.ConnectTo. It is labelled and reaped the same
+// way containers are.
+func (d *docker) CreateNetwork(name string, opts NetworkOptions) (Network, error) {
+	created, err := d.cli.NetworkCreate(d.ctx, name, types.NetworkCreate{
+		Driver: opts.Driver,
+		Labels: d.mergeSessionLabels(opts.Labels),
+	})
+	if err != nil {
+		return nil, dockererr.Wrap(err)
+	}
+	return &dockerNetwork{id: created.ID, name: name, cli: d.cli, ctx: d.ctx}, nil
+}
+
+// reapSessionNetworks removes every network labelled with this session,
+// e.g. on shutdown.
+func (d *docker) reapSessionNetworks(sessionID string) error {
+	args := filters.NewArgs()
+	args.Add("label", labelSession+"="+sessionID)
+	return d.removeNetworksMatching(args, nil)
+}
+
+// pruneNetworks removes every reap-labelled network whose session label is
+// not this session's. It does not check which test binary created a network
+// or whether that network's session is still running.
+func (d *docker) pruneNetworks() error {
+	args := filters.NewArgs()
+	args.Add("label", labelReap+"=true")
+	return d.removeNetworksMatching(args, func(n types.NetworkResource) bool {
+		return n.Labels[labelSession] == d.sessionID
+	})
+}
+
+// removeNetworksMatching removes every network matching args for which
+// keep is nil or returns false. It keeps going after an individual removal
+// fails, returning the first error seen. A network still in use by a
+// running container is left alone: it isn't this function's job to tear
+// down containers.
+func (d *docker) removeNetworksMatching(args filters.Args, keep func(types.NetworkResource) bool) error {
+	networks, err := d.cli.NetworkList(d.ctx, types.NetworkListOptions{Filters: args})
+	if err != nil {
+		return dockererr.Wrap(err)
+	}
+
+	var firstErr error
+	for _, n := range networks {
+		if keep != nil && keep(n) {
+			continue
+		}
+		if err := d.cli.NetworkRemove(d.ctx, n.ID); err != nil && firstErr == nil {
+			firstErr = dockererr.Wrap(err)
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,143 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestCreateNetworkShouldStampSessionAndReapLabels(t *testing.T) {
+	var createdOptions types.NetworkCreate
+	networkCreate := func(ctx context.Context, name string, options types.NetworkCreate) (types.NetworkCreateResponse, error) {
+		createdOptions = options
+		return types.NetworkCreateResponse{ID: "aNetworkId"}, nil
+	}
+
+	d := &docker{cli: &mockDockerClient{networkCreate: networkCreate}, ctx: context.Background(), sessionID: "aSessionId"}
+
+	n, err := d.CreateNetwork("my-net", NetworkOptions{Driver: "bridge"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "aNetworkId", n.ID())
+	assert.Equal(t, "my-net", n.Name())
+	assert.Equal(t, "bridge", createdOptions.Driver)
+	assert.Equal(t, "aSessionId", createdOptions.Labels[labelSession])
+	assert.Equal(t, "true", createdOptions.Labels[labelReap])
+}
+
+func TestNetworkRemoveShouldRemoveTheNetwork(t *testing.T) {
+	var removedID string
+	networkRemove := func(ctx context.Context, networkID string) error {
+		removedID = networkID
+		return nil
+	}
+
+	n := &dockerNetwork{id: "aNetworkId", cli: &mockDockerClient{networkRemove: networkRemove}, ctx: context.Background()}
+
+	err := n.Remove()
+
+	require.NoError(t, err)
+	assert.Equal(t, "aNetworkId", removedID)
+}
+
+func TestConnectToShouldAttachTheContainerWithAliases(t *testing.T) {
+	var usedNetworkID, usedContainerID string
+	var usedConfig *network.EndpointSettings
+	networkConnect := func(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+		usedNetworkID, usedContainerID, usedConfig = networkID, containerID, config
+		return nil
+	}
+
+	c := &dockerContainer{id: "aContainerId", cli: &mockDockerClient{networkConnect: networkConnect}, ctx: context.Background()}
+	n := &dockerNetwork{id: "aNetworkId"}
+
+	err := c.ConnectTo(context.Background(), n, "db", "postgres")
+
+	require.NoError(t, err)
+	assert.Equal(t, "aNetworkId", usedNetworkID)
+	assert.Equal(t, "aContainerId", usedContainerID)
+	assert.Equal(t, []string{"db", "postgres"}, usedConfig.Aliases)
+}
+
+func TestDisconnectFromShouldDetachTheContainer(t *testing.T) {
+	var usedNetworkID, usedContainerID string
+	networkDisconnect := func(ctx context.Context, networkID, containerID string, force bool) error {
+		usedNetworkID, usedContainerID = networkID, containerID
+		return nil
+	}
+
+	c := &dockerContainer{id: "aContainerId", cli: &mockDockerClient{networkDisconnect: networkDisconnect}, ctx: context.Background()}
+	n := &dockerNetwork{id: "aNetworkId"}
+
+	err := c.DisconnectFrom(context.Background(), n)
+
+	require.NoError(t, err)
+	assert.Equal(t, "aNetworkId", usedNetworkID)
+	assert.Equal(t, "aContainerId", usedContainerID)
+}
+
+func TestPruneShouldRemoveReapLabelledNetworksFromOtherSessions(t *testing.T) {
+	var removedIDs []string
+	networkList := func(ctx context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error) {
+		return []types.NetworkResource{
+			{ID: "ownedByThisSession", Labels: map[string]string{labelSession: "thisSession"}},
+			{ID: "leakedFromAPriorRun", Labels: map[string]string{labelSession: "aDeadSession"}},
+		}, nil
+	}
+	networkRemove := func(ctx context.Context, networkID string) error {
+		removedIDs = append(removedIDs, networkID)
+		return nil
+	}
+
+	mdc := &mockDockerClient{networkList: networkList, networkRemove: networkRemove}
+	d := &docker{cli: mdc, ctx: context.Background(), sessionID: "thisSession"}
+
+	err := d.Prune()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"leakedFromAPriorRun"}, removedIDs)
+}
+
+func TestRunWithOptionsShouldAttachToNetworksWithAliases(t *testing.T) {
+	var usedConfig *network.NetworkingConfig
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+		usedConfig = networkingConfig
+		return container.ContainerCreateCreatedBody{ID: "aContainerId"}, nil
+	}
+
+	d := &docker{cli: &mockDockerClient{containerCreate: containerCreate}, ctx: context.Background()}
+
+	_, err := d.RunWithOptions(RunOptions{
+		Name:           "name",
+		Image:          "image",
+		Networks:       []string{"my-net"},
+		NetworkAliases: map[string][]string{"my-net": {"db"}},
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, usedConfig)
+	require.Contains(t, usedConfig.EndpointsConfig, "my-net")
+	assert.Equal(t, []string{"db"}, usedConfig.EndpointsConfig["my-net"].Aliases)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"strconv"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// PortSpec builds a single container port binding. Use Port to start one,
+// then either Published for a daemon-assigned host port or Bind for an
+// explicit host address and port.
+type PortSpec struct {
+	containerPort nat.Port
+	hostIP        string
+	hostPort      string
+}
+
+// Port starts a PortSpec for containerPort, e.g. "27017/tcp" or "8080".
+func Port(containerPort string) *PortSpec {
+	return &PortSpec{containerPort: nat.Port(containerPort)}
+}
+
+// Published exposes the port on a host port chosen by the daemon; pass 0
+// for a random free port, or a specific port number to request it.
+func (p *PortSpec) Published(hostPort int) *PortSpec {
+	p.hostIP = ""
+	p.hostPort = strconv.Itoa(hostPort)
+	return p
+}
+
+// Bind exposes the port on a specific host IP and port, e.g. for binding
+// to loopback only.
+func (p *PortSpec) Bind(hostIP string, hostPort int) *PortSpec {
+	p.hostIP = hostIP
+	p.hostPort = strconv.Itoa(hostPort)
+	return p
+}
+
+// natPortBindings turns specs into the PortSet/PortMap pair ContainerCreate
+// expects: the set of ports to expose, and where each is bound on the host.
+func natPortBindings(specs []*PortSpec) (nat.PortSet, nat.PortMap) {
+	exposedPorts := nat.PortSet{}
+	bindings := nat.PortMap{}
+
+	for _, s := range specs {
+		exposedPorts[s.containerPort] = struct{}{}
+		bindings[s.containerPort] = append(bindings[s.containerPort], nat.PortBinding{
+			HostIP:   s.hostIP,
+			HostPort: s.hostPort,
+		})
+	}
+
+	return exposedPorts, bindings
+}
@@ -0,0 +1,46 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortPublishedShouldRequestARandomHostPortWhenGivenZero(t *testing.T) {
+	exposedPorts, bindings := natPortBindings([]*PortSpec{Port("27017/tcp").Published(0)})
+
+	assert.Equal(t, nat.PortSet{nat.Port("27017/tcp"): {}}, exposedPorts)
+	require.Contains(t, bindings, nat.Port("27017/tcp"))
+	assert.Equal(t, []nat.PortBinding{{HostIP: "", HostPort: "0"}}, bindings[nat.Port("27017/tcp")])
+}
+
+func TestPortBindShouldRequestASpecificHostIPAndPort(t *testing.T) {
+	_, bindings := natPortBindings([]*PortSpec{Port("27017/tcp").Bind("127.0.0.1", 27017)})
+
+	assert.Equal(t, []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "27017"}}, bindings[nat.Port("27017/tcp")])
+}
+
+func TestNatPortBindingsShouldReturnEmptyMapsWhenGivenNoSpecs(t *testing.T) {
+	exposedPorts, bindings := natPortBindings(nil)
+
+	assert.Empty(t, exposedPorts)
+	assert.Empty(t, bindings)
+}
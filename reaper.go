@@ -0,0 +1,157 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/HotelsDotCom/go-docker-client/dockererr"
+	"github.com/HotelsDotCom/go-logger"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/google/uuid"
+	"golang.org/x/net/context"
+)
+
+const (
+	// labelSession is stamped on every container this client creates, so a
+	// session can find (and only remove) its own containers.
+	labelSession = "com.hotelsdotcom.go-docker-client.session"
+
+	// labelReap marks a container as eligible for reaping, by this or any
+	// later session, once its own session is gone.
+	labelReap = "com.hotelsdotcom.go-docker-client.reap"
+)
+
+// newSessionID is a seam for tests.
+var newSessionID = func() string {
+	return uuid.New().String()
+}
+
+// DockerOption configures a Docker client created by NewDockerWithOptions.
+type DockerOption func(*docker)
+
+// WithReaper arranges for this session's containers to be removed if the
+// process receives SIGINT or SIGTERM, so a killed test run doesn't leak
+// them. It cannot protect against SIGKILL; Prune exists to clean up after
+// a run that was killed outright.
+func WithReaper(enabled bool) DockerOption {
+	return func(d *docker) { d.reaperEnabled = enabled }
+}
+
+// WithSessionLabels adds extra labels, alongside the session/reap labels
+// go-docker-client stamps automatically, to every container this client
+// creates.
+func WithSessionLabels(labels map[string]string) DockerOption {
+	return func(d *docker) { d.sessionLabels = labels }
+}
+
+// NewDockerWithOptions creates a Docker client from the environment, as
+// NewDocker does, with the given options applied.
+func NewDockerWithOptions(opts ...DockerOption) (Docker, error) {
+	cli, err := newDockerClient()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &docker{cli: cli, ctx: context.Background(), sessionID: newSessionID()}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.reaperEnabled {
+		d.installReaper()
+	}
+	return d, nil
+}
+
+// mergeSessionLabels returns labels with this session's bookkeeping labels
+// (and any WithSessionLabels) merged in, without mutating labels.
+func (d *docker) mergeSessionLabels(labels map[string]string) map[string]string {
+	merged := map[string]string{
+		labelSession: d.sessionID,
+		labelReap:    "true",
+	}
+	for k, v := range d.sessionLabels {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return merged
+}
+
+// installReaper removes this session's containers on SIGINT/SIGTERM.
+func (d *docker) installReaper() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		<-sigCh
+		if err := d.reapSession(d.sessionID); err != nil {
+			logger.Errorf("unable to reap session containers: %s", err)
+		}
+		os.Exit(1)
+	}()
+}
+
+// reapSession removes every container labelled with this session, e.g. on
+// shutdown.
+func (d *docker) reapSession(sessionID string) error {
+	args := filters.NewArgs()
+	args.Add("label", labelSession+"="+sessionID)
+	if err := d.removeContainersMatching(args, nil); err != nil {
+		return err
+	}
+	return d.reapSessionNetworks(sessionID)
+}
+
+// Prune removes every reap-labelled container and network whose session
+// label is not this session's. It does not check which test binary created
+// them or whether their session is still running.
+func (d *docker) Prune() error {
+	args := filters.NewArgs()
+	args.Add("label", labelReap+"=true")
+	if err := d.removeContainersMatching(args, func(c types.Container) bool {
+		return c.Labels[labelSession] == d.sessionID
+	}); err != nil {
+		return err
+	}
+	return d.pruneNetworks()
+}
+
+// removeContainersMatching force-removes every container matching args for
+// which keep is nil or returns false. It keeps going after an individual
+// removal fails, returning the first error seen.
+func (d *docker) removeContainersMatching(args filters.Args, keep func(types.Container) bool) error {
+	containers, err := d.cli.ContainerList(d.ctx, types.ContainerListOptions{All: true, Filters: args})
+	if err != nil {
+		return dockererr.Wrap(err)
+	}
+
+	var firstErr error
+	for _, c := range containers {
+		if keep != nil && keep(c) {
+			continue
+		}
+		if err := d.cli.ContainerRemove(d.ctx, c.ID, types.ContainerRemoveOptions{Force: true}); err != nil && firstErr == nil {
+			firstErr = dockererr.Wrap(err)
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,110 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestRunWithOptionsShouldStampSessionAndReapLabels(t *testing.T) {
+	ofClient, ofSessionID := newDockerClient, newSessionID
+	defer func() { newDockerClient, newSessionID = ofClient, ofSessionID }()
+
+	var createdLabels map[string]string
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+		createdLabels = config.Labels
+		return container.ContainerCreateCreatedBody{}, nil
+	}
+	mdc := &mockDockerClient{containerCreate: containerCreate}
+
+	newDockerClient = func() (dockerClient, error) { return mdc, nil }
+	newSessionID = func() string { return "aSessionId" }
+
+	d, err := NewDockerWithOptions()
+	require.NoError(t, err)
+
+	_, err = d.Run("name", "imagePath", nil, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "aSessionId", createdLabels[labelSession])
+	assert.Equal(t, "true", createdLabels[labelReap])
+}
+
+func TestWithSessionLabelsShouldBeMergedIntoCreatedContainers(t *testing.T) {
+	of := newDockerClient
+	defer func() { newDockerClient = of }()
+
+	var createdLabels map[string]string
+	containerCreate := func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+		createdLabels = config.Labels
+		return container.ContainerCreateCreatedBody{}, nil
+	}
+	mdc := &mockDockerClient{containerCreate: containerCreate}
+
+	newDockerClient = func() (dockerClient, error) { return mdc, nil }
+
+	d, err := NewDockerWithOptions(WithSessionLabels(map[string]string{"team": "platform"}))
+	require.NoError(t, err)
+
+	_, err = d.Run("name", "imagePath", nil, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "platform", createdLabels["team"])
+}
+
+func TestPruneShouldRemoveReapLabelledContainersFromOtherSessions(t *testing.T) {
+	var removedIDs []string
+	containerList := func(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+		return []types.Container{
+			{ID: "ownedByThisSession", Labels: map[string]string{labelSession: "thisSession"}},
+			{ID: "leakedFromAPriorRun", Labels: map[string]string{labelSession: "aDeadSession"}},
+		}, nil
+	}
+	containerRemove := func(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error {
+		removedIDs = append(removedIDs, containerID)
+		return nil
+	}
+
+	mdc := &mockDockerClient{containerList: containerList, containerRemove: containerRemove}
+	d := &docker{cli: mdc, ctx: context.Background(), sessionID: "thisSession"}
+
+	err := d.Prune()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"leakedFromAPriorRun"}, removedIDs)
+}
+
+func TestPruneShouldReturnErrorWhenContainerListFails(t *testing.T) {
+	containerList := func(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+		return nil, assert.AnError
+	}
+
+	mdc := &mockDockerClient{containerList: containerList}
+	d := &docker{cli: mdc, ctx: context.Background(), sessionID: "thisSession"}
+
+	err := d.Prune()
+
+	assert.Equal(t, assert.AnError, err)
+}
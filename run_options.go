@@ -0,0 +1,67 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"github.com/docker/docker/api/types/mount"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// RunOptions bundles everything Docker.RunWithOptions needs to create and
+// start a container. Run is a thin wrapper around RunWithOptions for the
+// common case of just needing a name, image, env and ports.
+type RunOptions struct {
+	// Name is the container name.
+	Name string
+
+	// Image is the image reference to run, e.g. "mongo" or "mongo:4.0".
+	Image string
+
+	// Env is passed through to the container as "KEY=VALUE" strings.
+	Env []string
+
+	// Ports are port specs as understood by nat.ParsePortSpecs, e.g.
+	// "27017:27017" or "8080/tcp".
+	Ports []string
+
+	// PortSpecs is the typed alternative to Ports, built with Port(...):
+	// it's clearer about host IP/port intent and is what MappedPort and
+	// Endpoint are meant to be paired with.
+	PortSpecs []*PortSpec
+
+	// Platform pins the OS/architecture/variant to run as, e.g.
+	// &specs.Platform{OS: "linux", Architecture: "amd64"}. When set, a
+	// locally cached image that doesn't match is re-pulled for the
+	// requested platform. Nil means "whatever the daemon picks".
+	Platform *specs.Platform
+
+	// Labels are applied to the created container.
+	Labels map[string]string
+
+	// Networks attaches the container to existing user-defined networks by
+	// name at creation time, in addition to the default bridge network.
+	Networks []string
+
+	// NetworkAliases are the names a container is reachable as by other
+	// containers on a given network, keyed by network name. A network in
+	// Networks with no entry here gets no alias.
+	NetworkAliases map[string][]string
+
+	// Mounts are bind mounts, volumes or tmpfs mounts attached to the
+	// container.
+	Mounts []mount.Mount
+}
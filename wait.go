@@ -0,0 +1,220 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/HotelsDotCom/go-docker-client/dockererr"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"golang.org/x/net/context"
+)
+
+// WaitStrategy decides when a container started by Docker.RunAndWait is
+// ready to use, so callers don't have to poll Container.GetIP and roll
+// their own readiness loop.
+type WaitStrategy interface {
+	// Wait blocks until containerID is ready, ctx is done, or the strategy
+	// gives up, whichever comes first.
+	Wait(ctx context.Context, cli dockerClient, containerID string) error
+}
+
+// pollUntilReady runs check repeatedly, a pollInterval apart, until it
+// returns true, ctx is done, or an error occurs.
+func pollUntilReady(ctx context.Context, pollInterval time.Duration, check func() (bool, error)) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		ready, err := check()
+		if err != nil {
+			return err
+		}
+		if ready {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// logWaitStrategy is a WaitStrategy that waits for a log line pattern to
+// appear a given number of times.
+type logWaitStrategy struct {
+	pattern     *regexp.Regexp
+	occurrences int
+}
+
+// WaitForLog waits until pattern has matched a line of the container's
+// combined stdout/stderr occurrences times.
+func WaitForLog(pattern *regexp.Regexp, occurrences int) WaitStrategy {
+	return &logWaitStrategy{pattern: pattern, occurrences: occurrences}
+}
+
+func (s *logWaitStrategy) Wait(ctx context.Context, cli dockerClient, containerID string) error {
+	reader, err := cli.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Follow: true})
+	if err != nil {
+		return dockererr.Wrap(err)
+	}
+	defer reader.Close()
+
+	matched := 0
+	scanner := bufio.NewScanner(reader)
+
+	done := make(chan error, 1)
+	go func() {
+		for scanner.Scan() {
+			if s.pattern.MatchString(scanner.Text()) {
+				matched++
+				if matched >= s.occurrences {
+					done <- nil
+					return
+				}
+			}
+		}
+		done <- fmt.Errorf("container logs closed before %q matched %d time(s)", s.pattern, s.occurrences)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
+
+// portWaitStrategy is a WaitStrategy that waits for a TCP port to accept
+// connections.
+type portWaitStrategy struct {
+	port    nat.Port
+	timeout time.Duration
+}
+
+// WaitForPort waits until port is dialable on the container, giving up
+// after timeout.
+func WaitForPort(port nat.Port, timeout time.Duration) WaitStrategy {
+	return &portWaitStrategy{port: port, timeout: timeout}
+}
+
+func (s *portWaitStrategy) Wait(ctx context.Context, cli dockerClient, containerID string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return pollUntilReady(ctx, 250*time.Millisecond, func() (bool, error) {
+		mapped, err := mappedPort(ctx, cli, containerID, s.port)
+		if err != nil {
+			return false, nil
+		}
+
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(dockerHost, mapped.Port()), 250*time.Millisecond)
+		if err != nil {
+			return false, nil
+		}
+		conn.Close()
+		return true, nil
+	})
+}
+
+// httpWaitStrategy is a WaitStrategy that waits for an HTTP endpoint to
+// respond with a status satisfying statusPredicate.
+type httpWaitStrategy struct {
+	port            nat.Port
+	path            string
+	statusPredicate func(status int) bool
+	tlsConfig       *tls.Config
+	timeout         time.Duration
+}
+
+// WaitForHTTP waits until a GET of path on port returns a status for which
+// statusPredicate returns true, giving up after timeout. A nil tlsConfig
+// means plain HTTP; a non-nil one is used to make HTTPS requests.
+func WaitForHTTP(port nat.Port, path string, statusPredicate func(status int) bool, tlsConfig *tls.Config, timeout time.Duration) WaitStrategy {
+	return &httpWaitStrategy{port: port, path: path, statusPredicate: statusPredicate, tlsConfig: tlsConfig, timeout: timeout}
+}
+
+func (s *httpWaitStrategy) Wait(ctx context.Context, cli dockerClient, containerID string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	scheme := "http"
+	client := &http.Client{Timeout: 250 * time.Millisecond}
+	if s.tlsConfig != nil {
+		scheme = "https"
+		client.Transport = &http.Transport{TLSClientConfig: s.tlsConfig}
+	}
+
+	return pollUntilReady(ctx, 250*time.Millisecond, func() (bool, error) {
+		mapped, err := mappedPort(ctx, cli, containerID, s.port)
+		if err != nil {
+			return false, nil
+		}
+
+		url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(dockerHost, mapped.Port()), s.path)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return false, err
+		}
+
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return false, nil
+		}
+		defer resp.Body.Close()
+
+		return s.statusPredicate(resp.StatusCode), nil
+	})
+}
+
+// healthyWaitStrategy is a WaitStrategy that waits for the container's
+// Docker HEALTHCHECK to report healthy.
+type healthyWaitStrategy struct {
+	timeout time.Duration
+}
+
+// WaitForHealthy waits until ContainerInspect reports State.Health.Status
+// of "healthy", giving up after timeout. The image must define a
+// HEALTHCHECK for this to ever succeed.
+func WaitForHealthy(timeout time.Duration) WaitStrategy {
+	return &healthyWaitStrategy{timeout: timeout}
+}
+
+func (s *healthyWaitStrategy) Wait(ctx context.Context, cli dockerClient, containerID string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return pollUntilReady(ctx, 250*time.Millisecond, func() (bool, error) {
+		inspect, err := cli.ContainerInspect(ctx, containerID)
+		if err != nil {
+			return false, dockererr.Wrap(err)
+		}
+		if inspect.State == nil || inspect.State.Health == nil {
+			return false, nil
+		}
+		return inspect.State.Health.Status == types.Healthy, nil
+	})
+}
@@ -0,0 +1,179 @@
+/*
+Copyright (C) 2018 Expedia Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"io"
+)
+
+func containerInspectReturningMappedPort(containerPort nat.Port, hostPort string) func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	return func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+		return types.ContainerJSON{
+			NetworkSettings: &types.NetworkSettings{
+				NetworkSettingsBase: types.NetworkSettingsBase{
+					Ports: nat.PortMap{
+						containerPort: []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: hostPort}},
+					},
+				},
+			},
+		}, nil
+	}
+}
+
+func TestWaitForLogShouldReturnOnceOccurrencesSeen(t *testing.T) {
+	logs := "starting\nwaiting for connections\nwaiting for connections\n"
+	mdc := &mockDockerClient{containerLogs: func(ctx context.Context, containerID string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(logs)), nil
+	}}
+
+	strategy := WaitForLog(regexp.MustCompile("waiting for connections"), 2)
+	err := strategy.Wait(context.Background(), mdc, "aContainerId")
+
+	assert.NoError(t, err)
+}
+
+func TestWaitForLogShouldReturnErrorWhenLogsCloseBeforeOccurrencesSeen(t *testing.T) {
+	mdc := &mockDockerClient{containerLogs: func(ctx context.Context, containerID string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("starting\n")), nil
+	}}
+
+	strategy := WaitForLog(regexp.MustCompile("ready"), 1)
+	err := strategy.Wait(context.Background(), mdc, "aContainerId")
+
+	assert.Error(t, err)
+}
+
+func TestWaitForLogShouldReturnErrorWhenContainerLogsFails(t *testing.T) {
+	mdc := &mockDockerClient{containerLogs: func(ctx context.Context, containerID string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+		return nil, assert.AnError
+	}}
+
+	strategy := WaitForLog(regexp.MustCompile("ready"), 1)
+	err := strategy.Wait(context.Background(), mdc, "aContainerId")
+
+	assert.Equal(t, assert.AnError, err)
+}
+
+func TestWaitForPortShouldReturnOnceThePortIsDialable(t *testing.T) {
+	ln, err := net.Listen("tcp", dockerHost+":0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	_, portStr, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+
+	mdc := &mockDockerClient{containerInspect: containerInspectReturningMappedPort("27017/tcp", portStr)}
+
+	strategy := WaitForPort(nat.Port("27017/tcp"), 2*time.Second)
+	err = strategy.Wait(context.Background(), mdc, "aContainerId")
+
+	assert.NoError(t, err)
+}
+
+func TestWaitForPortShouldGiveUpWhenNothingIsListening(t *testing.T) {
+	mdc := &mockDockerClient{containerInspect: containerInspectReturningMappedPort("27017/tcp", "1")}
+
+	strategy := WaitForPort(nat.Port("27017/tcp"), 300*time.Millisecond)
+	err := strategy.Wait(context.Background(), mdc, "aContainerId")
+
+	assert.Error(t, err)
+}
+
+func TestWaitForHTTPShouldReturnOnceStatusPredicateIsSatisfied(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	_, portStr, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+
+	mdc := &mockDockerClient{containerInspect: containerInspectReturningMappedPort("8080/tcp", portStr)}
+
+	strategy := WaitForHTTP(nat.Port("8080/tcp"), "/", func(status int) bool { return status == http.StatusOK }, nil, 2*time.Second)
+	err = strategy.Wait(context.Background(), mdc, "aContainerId")
+
+	assert.NoError(t, err)
+}
+
+func TestWaitForHTTPShouldGiveUpWhenStatusNeverSatisfiesPredicate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	_, portStr, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+
+	mdc := &mockDockerClient{containerInspect: containerInspectReturningMappedPort("8080/tcp", portStr)}
+
+	strategy := WaitForHTTP(nat.Port("8080/tcp"), "/", func(status int) bool { return status == http.StatusOK }, nil, 300*time.Millisecond)
+	err = strategy.Wait(context.Background(), mdc, "aContainerId")
+
+	assert.Error(t, err)
+}
+
+func TestWaitForHealthyShouldReturnOnceContainerReportsHealthy(t *testing.T) {
+	calls := 0
+	mdc := &mockDockerClient{containerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+		calls++
+		status := types.Starting
+		if calls > 1 {
+			status = types.Healthy
+		}
+		return types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				State: &types.ContainerState{Health: &types.Health{Status: status}},
+			},
+		}, nil
+	}}
+
+	strategy := WaitForHealthy(2 * time.Second)
+	err := strategy.Wait(context.Background(), mdc, "aContainerId")
+
+	assert.NoError(t, err)
+}
+
+func TestWaitForHealthyShouldGiveUpWhenTheImageHasNoHealthcheck(t *testing.T) {
+	mdc := &mockDockerClient{containerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+		return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{}}}, nil
+	}}
+
+	strategy := WaitForHealthy(300 * time.Millisecond)
+	err := strategy.Wait(context.Background(), mdc, "aContainerId")
+
+	assert.Error(t, err)
+}